@@ -0,0 +1,39 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+// Condition types set on GlanceAPIStatus.Conditions, one per install stage
+// plus the roll-up Ready condition.
+const (
+	// ConditionStorageReady indicates whether the image cache PVC is Bound.
+	ConditionStorageReady = "StorageReady"
+
+	// ConditionDatabaseReady indicates whether the MariaDB schema has been
+	// created.
+	ConditionDatabaseReady = "DatabaseReady"
+
+	// ConditionDBSyncReady indicates whether the db-sync Job has completed.
+	ConditionDBSyncReady = "DBSyncReady"
+
+	// ConditionDeploymentReady indicates whether the Glance API Deployment
+	// has its desired number of ready replicas.
+	ConditionDeploymentReady = "DeploymentReady"
+
+	// ConditionReady is the roll-up of every other condition: true only once
+	// storage, the database, db-sync and the Deployment are all ready.
+	ConditionReady = "Ready"
+)
@@ -0,0 +1,136 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GlanceAPISpec defines the desired state of GlanceAPI
+type GlanceAPISpec struct {
+	// ContainerImage - the Glance API container image to run
+	ContainerImage string `json:"containerImage"`
+
+	// Replicas - the number of Glance API replicas to run
+	// +kubebuilder:default=1
+	Replicas int32 `json:"replicas"`
+
+	// DatabaseInstance - the name of the MariaDB instance backing Glance
+	DatabaseInstance string `json:"databaseInstance"`
+
+	// DatabaseUser - the name of the database user created for Glance
+	// +kubebuilder:default=glance
+	DatabaseUser string `json:"databaseUser"`
+
+	// Secret containing the database and keystone passwords
+	Secret string `json:"secret"`
+
+	// Storage holds the image cache PVC settings
+	// +kubebuilder:default={}
+	Storage GlanceAPIStorageSpec `json:"storage,omitempty"`
+
+	// ExtraEnv - additional environment variables to set on the glance-api
+	// container, appended after the operator-managed ones so a value here
+	// overrides an operator-set variable of the same name
+	// +optional
+	ExtraEnv []corev1.EnvVar `json:"extraEnv,omitempty"`
+
+	// ExtraEnvFrom - additional ConfigMap/Secret sources to populate the
+	// glance-api container environment from, appended after the
+	// operator-managed sources
+	// +optional
+	ExtraEnvFrom []corev1.EnvFromSource `json:"extraEnvFrom,omitempty"`
+
+	// CustomConfigOverrides - additional glance.conf.d snippets, keyed by
+	// file name, layered on top of the base glance.conf the operator
+	// generates
+	// +optional
+	CustomConfigOverrides map[string]string `json:"customConfigOverrides,omitempty"`
+}
+
+// GlanceAPIStorageSpec defines the image cache PVC owned by a GlanceAPI
+//
+// BREAKING CHANGE: this struct replaces the flat top-level
+// GlanceAPISpec.StorageClass/StorageRequest fields with
+// spec.storage.class/spec.storage.request so RetainOnDelete had a natural
+// home alongside them. Existing CRs written against the flat fields will
+// have spec.storageClass/spec.storageRequest silently dropped as unknown
+// fields on apply; they must be migrated to spec.storage.class/
+// spec.storage.request (and may set spec.storage.retainOnDelete) before
+// upgrading.
+type GlanceAPIStorageSpec struct {
+	// Class - the storage class used for the Glance image cache PVC
+	Class string `json:"class,omitempty"`
+
+	// Request - the size of the Glance image cache PVC
+	// +kubebuilder:default="10G"
+	Request string `json:"request,omitempty"`
+
+	// RetainOnDelete - keep the image cache PVC when the GlanceAPI is
+	// deleted instead of deleting it along with the other owned resources
+	// +kubebuilder:default=false
+	RetainOnDelete bool `json:"retainOnDelete,omitempty"`
+}
+
+// GlanceAPIStatus defines the observed state of GlanceAPI
+type GlanceAPIStatus struct {
+	// DbSyncHash - hash of the last successfully run db-sync Job
+	DbSyncHash string `json:"dbSyncHash,omitempty"`
+
+	// Conditions - the set of conditions describing the state of the
+	// resources GlanceAPI owns
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].reason"
+// +kubebuilder:printcolumn:name="Message",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].message"
+
+// GlanceAPI is the Schema for the glanceapis API
+type GlanceAPI struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GlanceAPISpec   `json:"spec,omitempty"`
+	Status GlanceAPIStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// GlanceAPIList contains a list of GlanceAPI
+type GlanceAPIList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GlanceAPI `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GlanceAPI{}, &GlanceAPIList{})
+}
+
+// GetPodLabels returns the common set of labels applied to all pods/objects
+// owned by this GlanceAPI instance.
+func (instance *GlanceAPI) GetPodLabels() map[string]string {
+	return map[string]string{
+		"service": "glance",
+		"owner":   instance.Name,
+	}
+}
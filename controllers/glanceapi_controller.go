@@ -22,21 +22,40 @@ import (
 	"github.com/go-logr/logr"
 	glancev1beta1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
 	glance "github.com/openstack-k8s-operators/glance-operator/pkg"
+	"github.com/openstack-k8s-operators/glance-operator/pkg/conditions"
+	"github.com/openstack-k8s-operators/glance-operator/pkg/install"
 	util "github.com/openstack-k8s-operators/lib-common/pkg/util"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"fmt"
-	"reflect"
 	"time"
 )
 
+// glanceFinalizer is set on every GlanceAPI so the controller can run
+// reconcileDelete before the API server garbage-collects owner-ref'd
+// resources; it is needed because the MariaDB schema object created via
+// glance.SchemaObject is unstructured and does not carry an owner
+// reference, so it would otherwise be leaked on deletion.
+const glanceFinalizer = "glance.openstack.org/finalizer"
+
+// stepConditions maps install.Step names to the GlanceAPIStatus condition
+// they drive.
+var stepConditions = map[string]string{
+	"pvc":        glancev1beta1.ConditionStorageReady,
+	"db-schema":  glancev1beta1.ConditionDatabaseReady,
+	"db-sync":    glancev1beta1.ConditionDBSyncReady,
+	"deployment": glancev1beta1.ConditionDeploymentReady,
+}
+
 // GlanceAPIReconciler reconciles a GlanceAPI object
 type GlanceAPIReconciler struct {
 	client.Client
@@ -52,182 +71,219 @@ type GlanceAPIReconciler struct {
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;create;update;delete;
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;create;update;delete;
 func (r *GlanceAPIReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
-	_ = context.Background()
+	ctx := context.Background()
 	_ = r.Log.WithValues("glanceapi", req.NamespacedName)
 
 	// Fetch the Glance instance
 	instance := &glancev1beta1.GlanceAPI{}
-	err := r.Client.Get(context.TODO(), req.NamespacedName, instance)
+	err := r.Client.Get(ctx, req.NamespacedName, instance)
 	if err != nil {
 		if k8s_errors.IsNotFound(err) {
-			// Request object not found, could have been deleted after reconcile request.
-			// Owned objects are automatically garbage collected.
-			// For additional cleanup logic use finalizers. Return and don't requeue.
+			// Request object not found, could have been deleted after reconcile
+			// request. Cleanup already ran in reconcileDelete via the
+			// glanceFinalizer before the object was removed, so there's
+			// nothing left to do here. Return and don't requeue.
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
 		return ctrl.Result{}, err
 	}
 
-	// PVC
-	pvc := glance.Pvc(instance, r.Scheme)
-
-	foundPvc := &corev1.PersistentVolumeClaim{}
-	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: pvc.Name, Namespace: pvc.Namespace}, foundPvc)
-	if err != nil && k8s_errors.IsNotFound(err) {
-
-		r.Log.Info("Creating a new Pvc", "PersistentVolumeClaim.Namespace", pvc.Namespace, "Service.Name", pvc.Name)
-		err = r.Client.Create(context.TODO(), pvc)
-		if err != nil {
-			return ctrl.Result{}, err
-		}
-
-		return ctrl.Result{RequeueAfter: time.Second * 5}, err
-	} else if err != nil {
-		return ctrl.Result{}, err
+	if !instance.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, instance)
 	}
 
-	service := glance.Service(instance, r.Scheme)
-
-	// Check if this Service already exists
-	foundService := &corev1.Service{}
-	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: service.Name, Namespace: service.Namespace}, foundService)
-	if err != nil && k8s_errors.IsNotFound(err) {
-
-		r.Log.Info("Creating a new Service", "Service.Namespace", service.Namespace, "Service.Name", service.Name)
-		err = r.Client.Create(context.TODO(), service)
-		if err != nil {
+	if !controllerutil.ContainsFinalizer(instance, glanceFinalizer) {
+		controllerutil.AddFinalizer(instance, glanceFinalizer)
+		if err := r.Client.Update(ctx, instance); err != nil {
 			return ctrl.Result{}, err
 		}
-
-		return ctrl.Result{RequeueAfter: time.Second * 5}, err
-	} else if err != nil {
-		return ctrl.Result{}, err
 	}
 
-	// ConfigMap
 	configMap := glance.ConfigMap(instance, r.Scheme)
-	// Check if this ConfigMap already exists
-	foundConfigMap := &corev1.ConfigMap{}
-	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: configMap.Name, Namespace: configMap.Namespace}, foundConfigMap)
-	if err != nil && k8s_errors.IsNotFound(err) {
-		r.Log.Info("Creating a new ConfigMap", "ConfigMap.Namespace", configMap.Namespace, "Job.Name", configMap.Name)
-		err = r.Client.Create(context.TODO(), configMap)
-		if err != nil {
-			return ctrl.Result{}, err
-		}
-	} else if !reflect.DeepEqual(configMap.Data, foundConfigMap.Data) {
-		r.Log.Info("Updating ConfigMap")
-		foundConfigMap.Data = configMap.Data
-		err = r.Client.Update(context.TODO(), foundConfigMap)
-		if err != nil {
-			return ctrl.Result{}, err
-		}
-		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	configMapHash, err := util.ObjectHash(configMap)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("error calculating config map hash: %v", err)
 	}
 
-	// Create the DB Schema (unstructured so we don't explicitly import mariadb-operator code)
 	schemaObj, err := glance.SchemaObject(instance)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	foundSchema := &unstructured.Unstructured{}
-	foundSchema.SetGroupVersionKind(schemaObj.GroupVersionKind())
-	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: schemaObj.GetName(), Namespace: schemaObj.GetNamespace()}, foundSchema)
-	if err != nil && k8s_errors.IsNotFound(err) {
-		err := r.Client.Create(context.TODO(), &schemaObj)
-		if err != nil {
-			return ctrl.Result{}, err
-		}
-	} else if err != nil {
-		return ctrl.Result{}, err
-	} else {
-		completed, _, err := unstructured.NestedBool(foundSchema.UnstructuredContent(), "status", "completed")
-		if !completed {
-			r.Log.Info("Waiting on DB to be created...")
-			return ctrl.Result{RequeueAfter: time.Second * 5}, err
-		}
-	}
-
-	// Define a new Job object
 	job := glance.DbSyncJob(instance, r.Scheme)
 	dbSyncHash, err := util.ObjectHash(job)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("error calculating DB sync hash: %v", err)
 	}
 
-	requeue := true
+	deployment := glance.Deployment(instance, configMapHash, r.Scheme)
+
+	steps := []install.Step{
+		{Name: "pvc", Object: glance.Pvc(instance, r.Scheme), Ready: install.PVCBound},
+		{Name: "service", Object: glance.Service(instance, r.Scheme)},
+		{Name: "configmap", Object: configMap},
+		{Name: "db-schema", Object: &schemaObj, Ready: install.UnstructuredCompleted},
+	}
+
+	// The db-sync Job's spec is immutable once created, so it is only
+	// applied when its hash (and therefore its pod template) has changed
+	// since the last successful run; otherwise skip straight to the
+	// Deployment rollout.
 	if instance.Status.DbSyncHash != dbSyncHash {
-		requeue, err = glance.EnsureJob(job, r.Client, r.Log)
-		r.Log.Info("Running DB sync")
-		if err != nil {
-			return ctrl.Result{}, err
-		} else if requeue {
-			r.Log.Info("Waiting on DB sync")
-			return ctrl.Result{RequeueAfter: time.Second * 5}, err
-		}
+		steps = append(steps, install.Step{
+			Name:   "db-sync",
+			Object: job,
+			Ready:  install.JobSucceeded,
+			AfterReady: func(ctx context.Context) error {
+				if err := r.setDbSyncHash(instance, dbSyncHash); err != nil {
+					return err
+				}
+				return r.pipelineClient().Delete(ctx, job)
+			},
+		})
 	}
-	// db sync completed... okay to store the hash to disable it
-	if err := r.setDbSyncHash(instance, dbSyncHash); err != nil {
-		return ctrl.Result{}, err
+
+	steps = append(steps, install.Step{
+		Name:   "deployment",
+		Object: deployment,
+		Ready:  install.DeploymentReady(instance.Spec.Replicas),
+	})
+
+	pipeline := install.Pipeline{Client: r.pipelineClient(), Steps: steps}
+	result, err := pipeline.Run(ctx)
+
+	for _, name := range result.Completed {
+		if condType, ok := stepConditions[name]; ok {
+			conditions.SetCondition(&instance.Status.Conditions, condType, metav1.ConditionTrue, "Ready", fmt.Sprintf("%s is ready", name))
+		}
 	}
-	// delete the job
-	requeue, err = glance.DeleteJob(job, r.Client, r.Log)
+
 	if err != nil {
+		if condType, ok := stepConditions[result.Step]; ok {
+			conditions.SetCondition(&instance.Status.Conditions, condType, metav1.ConditionFalse, "Error", err.Error())
+		}
+		conditions.SetCondition(&instance.Status.Conditions, glancev1beta1.ConditionReady, metav1.ConditionFalse, "Error", err.Error())
+		if statusErr := r.Client.Status().Update(ctx, instance); statusErr != nil {
+			r.Log.Error(statusErr, "Failed to update GlanceAPI status")
+		}
 		return ctrl.Result{}, err
 	}
 
-	// Define a new Deployment object
-	configMapHash, err := util.ObjectHash(configMap)
-	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("error calculating config map hash: %v", err)
-	}
-	r.Log.Info("ConfigMapHash: ", "Data Hash:", configMapHash)
-	deployment := glance.Deployment(instance, configMapHash, r.Scheme)
-	deploymentHash, err := util.ObjectHash(deployment)
-	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("error deployment hash: %v", err)
-	}
-	r.Log.Info("DeploymentHash: ", "Deployment Hash:", deploymentHash)
-
-	// Check if this Deployment already exists
-	foundDeployment := &appsv1.Deployment{}
-	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: deployment.Name, Namespace: deployment.Namespace}, foundDeployment)
-	if err != nil && k8s_errors.IsNotFound(err) {
-		r.Log.Info("Creating a new Deployment", "Deployment.Namespace", deployment.Namespace, "Deployment.Name", deployment.Name)
-		err = r.Client.Create(context.TODO(), deployment)
-		if err != nil {
+	if result.Requeue {
+		if condType, ok := stepConditions[result.Step]; ok {
+			conditions.SetCondition(&instance.Status.Conditions, condType, metav1.ConditionFalse, "Waiting", result.Reason)
+		}
+		conditions.SetCondition(&instance.Status.Conditions, glancev1beta1.ConditionReady, metav1.ConditionFalse, "Waiting", fmt.Sprintf("waiting on %s: %s", result.Step, result.Reason))
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
 			return ctrl.Result{}, err
 		}
+		r.Log.Info("Waiting on install step", "Step", result.Step, "Reason", result.Reason)
+		return ctrl.Result{RequeueAfter: time.Second * 5}, nil
+	}
 
-		return ctrl.Result{RequeueAfter: time.Second * 10}, err
-
-	} else if err != nil {
+	conditions.SetCondition(&instance.Status.Conditions, glancev1beta1.ConditionReady, metav1.ConditionTrue, "Ready", "GlanceAPI is ready")
+	if err := r.Client.Status().Update(ctx, instance); err != nil {
 		return ctrl.Result{}, err
-	} else {
+	}
 
-		if instance.Status.DeploymentHash != deploymentHash {
-			r.Log.Info("Deployment Updated")
-			foundDeployment.Spec = deployment.Spec
-			err = r.Client.Update(context.TODO(), foundDeployment)
-			if err != nil {
+	return ctrl.Result{}, nil
+}
+
+func (r *GlanceAPIReconciler) pipelineClient() install.ResourceClient {
+	return install.NewResourceClient(r.Client)
+}
+
+// reconcileDelete tears down the resources GlanceAPI owns that are not
+// covered by owner-ref garbage collection, in an order that avoids losing
+// in-flight image uploads or leaking the MariaDB schema: drain the
+// Deployment to zero, remove the schema CR, remove the db-sync Job, and
+// finally the image cache PVC unless the user asked to keep it. The
+// Deployment stage requeues until its pods have terminated, and the schema and
+// PVC stages requeue until their resource is actually gone before moving on;
+// the db-sync Job is deleted without waiting for its removal.
+func (r *GlanceAPIReconciler) reconcileDelete(ctx context.Context, instance *glancev1beta1.GlanceAPI) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(instance, glanceFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	key := types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}
+
+	// Drain in-flight image uploads by scaling the Deployment to zero before
+	// anything it depends on (the schema, the cache PVC) is removed.
+	deployment := &appsv1.Deployment{}
+	err := r.Client.Get(ctx, key, deployment)
+	if err != nil && !k8s_errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	if err == nil {
+		if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != 0 {
+			zero := int32(0)
+			deployment.Spec.Replicas = &zero
+			r.Log.Info("Scaling Deployment to zero before delete", "Deployment.Name", deployment.Name)
+			if err := r.Client.Update(ctx, deployment); err != nil {
 				return ctrl.Result{}, err
 			}
-			if err := r.setDeploymentHash(instance, deploymentHash); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 5}, nil
+		}
+		if deployment.Status.Replicas != 0 {
+			r.Log.Info("Waiting for Deployment pods to terminate", "Deployment.Name", deployment.Name)
+			return ctrl.Result{RequeueAfter: time.Second * 5}, nil
+		}
+	}
+
+	// Delete the schema CR and wait for mariadb-operator to remove it.
+	schemaObj, err := glance.SchemaObject(instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	foundSchema := &unstructured.Unstructured{}
+	foundSchema.SetGroupVersionKind(schemaObj.GroupVersionKind())
+	err = r.Client.Get(ctx, key, foundSchema)
+	if err != nil && !k8s_errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	if err == nil {
+		if foundSchema.GetDeletionTimestamp().IsZero() {
+			r.Log.Info("Deleting db schema", "Schema.Name", foundSchema.GetName())
+			if err := r.Client.Delete(ctx, foundSchema); err != nil && !k8s_errors.IsNotFound(err) {
 				return ctrl.Result{}, err
 			}
+		}
+		r.Log.Info("Waiting for db schema removal", "Schema.Name", foundSchema.GetName())
+		return ctrl.Result{RequeueAfter: time.Second * 5}, nil
+	}
+
+	// Delete the db-sync Job, if it still exists.
+	job := glance.DbSyncJob(instance, r.Scheme)
+	if err := r.Client.Delete(ctx, job); err != nil && !k8s_errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
 
-			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	// Delete the image cache PVC unless the user asked to keep it.
+	if !instance.Spec.Storage.RetainOnDelete {
+		pvc := glance.Pvc(instance, r.Scheme)
+		foundPvc := &corev1.PersistentVolumeClaim{}
+		err := r.Client.Get(ctx, key, foundPvc)
+		if err != nil && !k8s_errors.IsNotFound(err) {
+			return ctrl.Result{}, err
 		}
-		if foundDeployment.Status.ReadyReplicas == instance.Spec.Replicas {
-			r.Log.Info("Deployment Replicas running:", "Replicas", foundDeployment.Status.ReadyReplicas)
-		} else {
-			r.Log.Info("Waiting on Glance Deployment...")
-			return ctrl.Result{RequeueAfter: time.Second * 5}, err
+		if err == nil {
+			if foundPvc.GetDeletionTimestamp().IsZero() {
+				r.Log.Info("Deleting image cache PVC", "PersistentVolumeClaim.Name", pvc.Name)
+				if err := r.Client.Delete(ctx, foundPvc); err != nil && !k8s_errors.IsNotFound(err) {
+					return ctrl.Result{}, err
+				}
+			}
+			r.Log.Info("Waiting for PVC removal", "PersistentVolumeClaim.Name", pvc.Name)
+			return ctrl.Result{RequeueAfter: time.Second * 5}, nil
 		}
 	}
 
+	controllerutil.RemoveFinalizer(instance, glanceFinalizer)
+	if err := r.Client.Update(ctx, instance); err != nil {
+		return ctrl.Result{}, err
+	}
 	return ctrl.Result{}, nil
 }
 
@@ -251,15 +307,3 @@ func (r *GlanceAPIReconciler) setDbSyncHash(api *glancev1beta1.GlanceAPI, hashSt
 	}
 	return nil
 }
-
-func (r *GlanceAPIReconciler) setDeploymentHash(instance *glancev1beta1.GlanceAPI, hashStr string) error {
-
-	if hashStr != instance.Status.DeploymentHash {
-		instance.Status.DeploymentHash = hashStr
-		if err := r.Client.Status().Update(context.TODO(), instance); err != nil {
-			return err
-		}
-	}
-	return nil
-
-}
@@ -0,0 +1,190 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	glancev1beta1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
+	glance "github.com/openstack-k8s-operators/glance-operator/pkg"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("adding client-go scheme: %v", err)
+	}
+	if err := glancev1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("adding glance scheme: %v", err)
+	}
+	scheme.AddKnownTypeWithName(glance.MariaDBDatabaseGVK(), &unstructured.Unstructured{})
+	return scheme
+}
+
+func newDeletingInstance(retainOnDelete bool) *glancev1beta1.GlanceAPI {
+	now := metav1.Now()
+	return &glancev1beta1.GlanceAPI{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "glance",
+			Namespace:         "openstack",
+			Finalizers:        []string{glanceFinalizer},
+			DeletionTimestamp: &now,
+		},
+		Spec: glancev1beta1.GlanceAPISpec{
+			ContainerImage:   "quay.io/example/glance:latest",
+			Replicas:         1,
+			DatabaseInstance: "openstack",
+			Secret:           "glance-secret",
+			Storage: glancev1beta1.GlanceAPIStorageSpec{
+				Request:        "10G",
+				RetainOnDelete: retainOnDelete,
+			},
+		},
+	}
+}
+
+// TestReconcileDeleteStuckSchemaRequeues verifies that reconcileDelete keeps
+// requeuing, and does not remove the finalizer, while the MariaDB schema CR
+// still carries its own finalizer (i.e. mariadb-operator hasn't finished
+// tearing it down yet).
+func TestReconcileDeleteStuckSchemaRequeues(t *testing.T) {
+	scheme := newTestScheme(t)
+	instance := newDeletingInstance(false)
+
+	schemaObj, err := glance.SchemaObject(instance)
+	if err != nil {
+		t.Fatalf("building schema object: %v", err)
+	}
+	schemaObj.SetFinalizers([]string{"mariadb.openstack.org/finalizer"})
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(instance, &schemaObj).Build()
+	r := &GlanceAPIReconciler{Client: c, Log: logr.Discard(), Scheme: scheme}
+
+	result, err := r.reconcileDelete(context.Background(), instance)
+	if err != nil {
+		t.Fatalf("reconcileDelete returned error: %v", err)
+	}
+	if result.RequeueAfter != time.Second*5 {
+		t.Fatalf("expected a 5s requeue while the schema is stuck, got %v", result.RequeueAfter)
+	}
+
+	if !controllerutil.ContainsFinalizer(instance, glanceFinalizer) {
+		t.Fatalf("finalizer should not be removed while the schema CR still exists")
+	}
+
+	found := &unstructured.Unstructured{}
+	found.SetGroupVersionKind(glance.MariaDBDatabaseGVK())
+	if err := c.Get(context.Background(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, found); err != nil {
+		t.Fatalf("schema CR should still be present (blocked by its own finalizer): %v", err)
+	}
+}
+
+// TestReconcileDeleteBoundPVCBlocksUntilRemoved verifies that reconcileDelete
+// requeues rather than removing the finalizer while a Bound PVC carrying the
+// kubernetes pv-protection finalizer is still being torn down, and that it
+// completes once the PVC is actually gone.
+func TestReconcileDeleteBoundPVCBlocksUntilRemoved(t *testing.T) {
+	scheme := newTestScheme(t)
+	instance := newDeletingInstance(false)
+
+	pvc := glance.Pvc(instance, scheme)
+	pvc.Status.Phase = corev1.ClaimBound
+	pvc.Finalizers = []string{"kubernetes.io/pvc-protection"}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(instance, pvc).Build()
+	r := &GlanceAPIReconciler{Client: c, Log: logr.Discard(), Scheme: scheme}
+
+	result, err := r.reconcileDelete(context.Background(), instance)
+	if err != nil {
+		t.Fatalf("reconcileDelete returned error: %v", err)
+	}
+	if result.RequeueAfter != time.Second*5 {
+		t.Fatalf("expected a 5s requeue while the PVC is still terminating, got %v", result.RequeueAfter)
+	}
+	if !controllerutil.ContainsFinalizer(instance, glanceFinalizer) {
+		t.Fatalf("finalizer should not be removed while the PVC still exists")
+	}
+
+	// The pv-protection finalizer is gone (simulating the kubelet finishing
+	// the unmount) so the next reconcile should see the PVC disappear and
+	// complete the teardown.
+	found := &corev1.PersistentVolumeClaim{}
+	key := types.NamespacedName{Name: pvc.Name, Namespace: pvc.Namespace}
+	if err := c.Get(context.Background(), key, found); err != nil {
+		t.Fatalf("getting PVC: %v", err)
+	}
+	found.Finalizers = nil
+	if err := c.Update(context.Background(), found); err != nil {
+		t.Fatalf("clearing PVC finalizer: %v", err)
+	}
+	if err := c.Delete(context.Background(), found); err != nil {
+		t.Fatalf("removing PVC: %v", err)
+	}
+
+	result, err = r.reconcileDelete(context.Background(), instance)
+	if err != nil {
+		t.Fatalf("reconcileDelete returned error: %v", err)
+	}
+	if result.RequeueAfter != 0 {
+		t.Fatalf("expected teardown to complete once the PVC is gone, got requeue %v", result.RequeueAfter)
+	}
+	if controllerutil.ContainsFinalizer(instance, glanceFinalizer) {
+		t.Fatalf("finalizer should have been removed once every owned resource is gone")
+	}
+}
+
+// TestReconcileDeleteRetainOnDeleteSkipsPVC verifies that a Bound PVC is left
+// untouched, and the finalizer removed immediately, when RetainOnDelete is set.
+func TestReconcileDeleteRetainOnDeleteSkipsPVC(t *testing.T) {
+	scheme := newTestScheme(t)
+	instance := newDeletingInstance(true)
+
+	pvc := glance.Pvc(instance, scheme)
+	pvc.Status.Phase = corev1.ClaimBound
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(instance, pvc).Build()
+	r := &GlanceAPIReconciler{Client: c, Log: logr.Discard(), Scheme: scheme}
+
+	result, err := r.reconcileDelete(context.Background(), instance)
+	if err != nil {
+		t.Fatalf("reconcileDelete returned error: %v", err)
+	}
+	if result.RequeueAfter != 0 {
+		t.Fatalf("expected teardown to complete without touching the retained PVC, got requeue %v", result.RequeueAfter)
+	}
+	if controllerutil.ContainsFinalizer(instance, glanceFinalizer) {
+		t.Fatalf("finalizer should have been removed")
+	}
+
+	found := &corev1.PersistentVolumeClaim{}
+	key := types.NamespacedName{Name: pvc.Name, Namespace: pvc.Namespace}
+	if err := c.Get(context.Background(), key, found); err != nil {
+		t.Fatalf("retained PVC should still exist: %v", err)
+	}
+}
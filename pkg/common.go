@@ -0,0 +1,29 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package glance
+
+import "k8s.io/apimachinery/pkg/runtime/schema"
+
+// MariaDBDatabaseGVK returns the GroupVersionKind of the mariadb-operator
+// MariaDBDatabase CR, kept here so callers don't hardcode it inline.
+func MariaDBDatabaseGVK() schema.GroupVersionKind {
+	return schema.GroupVersionKind{
+		Group:   "mariadb.openstack.org",
+		Version: "v1beta1",
+		Kind:    "MariaDBDatabase",
+	}
+}
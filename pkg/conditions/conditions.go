@@ -0,0 +1,53 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package conditions is a thin, reusable wrapper around
+// k8s.io/apimachinery/pkg/api/meta's status-condition helpers, so every
+// glance-operator controller manages its []metav1.Condition status the same
+// way instead of reimplementing the find/update-in-place dance per
+// controller.
+package conditions
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// SetCondition creates or updates the condition identified by condType on
+// conditions. The LastTransitionTime is only bumped when the status value
+// actually changes.
+func SetCondition(conditions *[]metav1.Condition, condType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:    condType,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+}
+
+// IsTrue reports whether condType is present on conditions and set to True.
+func IsTrue(conditions []metav1.Condition, condType string) bool {
+	return meta.IsStatusConditionTrue(conditions, condType)
+}
+
+// GetReason returns the Reason of condType, or "" if condType isn't present.
+func GetReason(conditions []metav1.Condition, condType string) string {
+	c := meta.FindStatusCondition(conditions, condType)
+	if c == nil {
+		return ""
+	}
+	return c.Reason
+}
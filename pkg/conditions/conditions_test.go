@@ -0,0 +1,74 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetConditionCreatesAndUpdates(t *testing.T) {
+	var conditions []metav1.Condition
+
+	SetCondition(&conditions, "StorageReady", metav1.ConditionFalse, "PVCPending", "waiting for PVC to bind")
+	if IsTrue(conditions, "StorageReady") {
+		t.Fatalf("expected StorageReady to be False")
+	}
+	if reason := GetReason(conditions, "StorageReady"); reason != "PVCPending" {
+		t.Fatalf("reason = %q, want PVCPending", reason)
+	}
+
+	SetCondition(&conditions, "StorageReady", metav1.ConditionTrue, "PVCBound", "PVC is bound")
+	if !IsTrue(conditions, "StorageReady") {
+		t.Fatalf("expected StorageReady to be True")
+	}
+	if reason := GetReason(conditions, "StorageReady"); reason != "PVCBound" {
+		t.Fatalf("reason = %q, want PVCBound", reason)
+	}
+	if len(conditions) != 1 {
+		t.Fatalf("len(conditions) = %d, want 1 (update in place)", len(conditions))
+	}
+}
+
+func TestSetConditionTracksMultipleTypes(t *testing.T) {
+	var conditions []metav1.Condition
+
+	SetCondition(&conditions, "StorageReady", metav1.ConditionTrue, "PVCBound", "PVC is bound")
+	SetCondition(&conditions, "DatabaseReady", metav1.ConditionFalse, "SchemaPending", "waiting for schema")
+
+	if !IsTrue(conditions, "StorageReady") {
+		t.Fatalf("expected StorageReady to be True")
+	}
+	if IsTrue(conditions, "DatabaseReady") {
+		t.Fatalf("expected DatabaseReady to be False")
+	}
+	if len(conditions) != 2 {
+		t.Fatalf("len(conditions) = %d, want 2", len(conditions))
+	}
+}
+
+func TestIsTrueAndGetReasonOnMissingCondition(t *testing.T) {
+	var conditions []metav1.Condition
+
+	if IsTrue(conditions, "Ready") {
+		t.Fatalf("expected IsTrue to be false for an absent condition")
+	}
+	if reason := GetReason(conditions, "Ready"); reason != "" {
+		t.Fatalf("reason = %q, want empty string for an absent condition", reason)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package glance
+
+import (
+	"fmt"
+
+	glancev1beta1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// confDotDPath is where the glance-api container looks for glance.conf.d
+// snippets layered on top of glance.conf.
+const confDotDPath = "/etc/glance/glance.conf.d"
+
+// ConfigMap returns the ConfigMap holding glance.conf, and any
+// CustomConfigOverrides snippets, for a GlanceAPI instance
+func ConfigMap(instance *glancev1beta1.GlanceAPI, scheme *runtime.Scheme) *corev1.ConfigMap {
+	data := map[string]string{
+		"glance.conf": glanceConf(instance),
+	}
+	for name, contents := range instance.Spec.CustomConfigOverrides {
+		data[name] = contents
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-config-data", instance.Name),
+			Namespace: instance.Namespace,
+			Labels:    instance.GetPodLabels(),
+		},
+		Data: data,
+	}
+
+	ctrl.SetControllerReference(instance, configMap, scheme)
+	return configMap
+}
+
+func glanceConf(instance *glancev1beta1.GlanceAPI) string {
+	return fmt.Sprintf(`[DEFAULT]
+debug = false
+
+[database]
+connection = mysql+pymysql://%s@%s/glance
+
+[keystone_authtoken]
+auth_type = password
+`, instance.Spec.DatabaseUser, instance.Spec.DatabaseInstance)
+}
@@ -0,0 +1,85 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package glance
+
+import (
+	"testing"
+
+	glancev1beta1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+func newTestInstance() *glancev1beta1.GlanceAPI {
+	return &glancev1beta1.GlanceAPI{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-glance",
+			Namespace: "openstack",
+		},
+		Spec: glancev1beta1.GlanceAPISpec{
+			ContainerImage:   "quay.io/example/glance-api:latest",
+			Replicas:         1,
+			DatabaseInstance: "openstack",
+			DatabaseUser:     "glance",
+		},
+	}
+}
+
+func TestConfigMapRendersCustomConfigOverrides(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("adding client-go scheme: %v", err)
+	}
+	if err := glancev1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("adding glance scheme: %v", err)
+	}
+
+	instance := newTestInstance()
+	instance.Spec.CustomConfigOverrides = map[string]string{
+		"cache.conf": "[image_cache]\nenabled = true\n",
+	}
+
+	configMap := ConfigMap(instance, scheme)
+
+	if _, ok := configMap.Data["glance.conf"]; !ok {
+		t.Fatalf("expected base glance.conf to be present")
+	}
+	if got, want := configMap.Data["cache.conf"], "[image_cache]\nenabled = true\n"; got != want {
+		t.Fatalf("cache.conf = %q, want %q", got, want)
+	}
+}
+
+func TestConfigMapDataChangesWithOverrides(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("adding client-go scheme: %v", err)
+	}
+	if err := glancev1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("adding glance scheme: %v", err)
+	}
+
+	without := ConfigMap(newTestInstance(), scheme)
+
+	withOverrides := newTestInstance()
+	withOverrides.Spec.CustomConfigOverrides = map[string]string{"store.conf": "[glance_store]\n"}
+	with := ConfigMap(withOverrides, scheme)
+
+	if len(without.Data) == len(with.Data) {
+		t.Fatalf("expected CustomConfigOverrides to change the rendered ConfigMap data")
+	}
+}
@@ -0,0 +1,109 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package glance
+
+import (
+	"sort"
+
+	glancev1beta1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Deployment returns the Glance API Deployment. configMapHash is stamped onto
+// the pod template annotations so that config changes trigger a rollout.
+func Deployment(instance *glancev1beta1.GlanceAPI, configMapHash string, scheme *runtime.Scheme) *appsv1.Deployment {
+	replicas := instance.Spec.Replicas
+
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      "config-data",
+			MountPath: "/etc/glance/glance.conf",
+			SubPath:   "glance.conf",
+		},
+	}
+
+	overrideNames := make([]string, 0, len(instance.Spec.CustomConfigOverrides))
+	for name := range instance.Spec.CustomConfigOverrides {
+		overrideNames = append(overrideNames, name)
+	}
+	sort.Strings(overrideNames)
+	for _, name := range overrideNames {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "config-data",
+			MountPath: confDotDPath + "/" + name,
+			SubPath:   name,
+		})
+	}
+
+	env := append([]corev1.EnvVar{}, instance.Spec.ExtraEnv...)
+	envFrom := append([]corev1.EnvFromSource{}, instance.Spec.ExtraEnvFrom...)
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      instance.Name,
+			Namespace: instance.Namespace,
+			Labels:    instance.GetPodLabels(),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: instance.GetPodLabels(),
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: instance.GetPodLabels(),
+					Annotations: map[string]string{
+						"glance.openstack.org/config-hash": configMapHash,
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "glance-api",
+							Image: instance.Spec.ContainerImage,
+							Ports: []corev1.ContainerPort{
+								{ContainerPort: 9292, Name: "glance"},
+							},
+							VolumeMounts: volumeMounts,
+							Env:          env,
+							EnvFrom:      envFrom,
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "config-data",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{
+										Name: ConfigMap(instance, scheme).Name,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	ctrl.SetControllerReference(instance, deployment, scheme)
+	return deployment
+}
@@ -0,0 +1,78 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package glance
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestDeploymentAppendsExtraEnvAfterOperatorEnv(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("adding client-go scheme: %v", err)
+	}
+
+	instance := newTestInstance()
+	instance.Spec.ExtraEnv = []corev1.EnvVar{
+		{Name: "GLANCE_LOG_LEVEL", Value: "DEBUG"},
+	}
+	instance.Spec.ExtraEnvFrom = []corev1.EnvFromSource{
+		{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "proxy-env"}}},
+	}
+
+	deployment := Deployment(instance, "somehash", scheme)
+	container := deployment.Spec.Template.Spec.Containers[0]
+
+	if len(container.Env) != 1 || container.Env[len(container.Env)-1].Name != "GLANCE_LOG_LEVEL" {
+		t.Fatalf("expected ExtraEnv to be appended, got %+v", container.Env)
+	}
+	if len(container.EnvFrom) != 1 || container.EnvFrom[0].ConfigMapRef.Name != "proxy-env" {
+		t.Fatalf("expected ExtraEnvFrom to be wired onto the container, got %+v", container.EnvFrom)
+	}
+}
+
+func TestDeploymentMountsCustomConfigOverrides(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("adding client-go scheme: %v", err)
+	}
+
+	instance := newTestInstance()
+	instance.Spec.CustomConfigOverrides = map[string]string{
+		"cache.conf": "[image_cache]\nenabled = true\n",
+	}
+
+	deployment := Deployment(instance, "somehash", scheme)
+	container := deployment.Spec.Template.Spec.Containers[0]
+
+	var found bool
+	for _, vm := range container.VolumeMounts {
+		if vm.SubPath == "cache.conf" {
+			found = true
+			if vm.MountPath != confDotDPath+"/cache.conf" {
+				t.Fatalf("cache.conf mounted at %q, want %s/cache.conf", vm.MountPath, confDotDPath)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected a VolumeMount for the cache.conf override, got %+v", container.VolumeMounts)
+	}
+}
@@ -0,0 +1,112 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Step is one entry in an install Pipeline: a resource to apply and,
+// optionally, a readiness gate the pipeline must see pass before it moves on
+// to the next step.
+type Step struct {
+	// Name identifies the step in logs and errors, e.g. "pvc", "db-sync".
+	Name string
+	// Object is the desired state of the resource, already named/namespaced.
+	Object client.Object
+	// Ready reports whether Object has reached a state later steps can rely
+	// on. A nil Ready means the step is fire-and-forget.
+	Ready ReadyFunc
+	// AfterReady runs on every Run in which Ready reports true, before the
+	// pipeline advances to the next step. Used for steps like the db-sync
+	// Job whose resource should be torn down as soon as it has served its
+	// purpose.
+	AfterReady func(ctx context.Context) error
+}
+
+// Pipeline applies an ordered list of Steps, stopping at the first one that
+// is not yet ready so the caller can requeue and let the next reconcile
+// resume from there.
+type Pipeline struct {
+	Client ResourceClient
+	Steps  []Step
+}
+
+// Result reports how far a Pipeline got on one Run.
+type Result struct {
+	// Completed lists, in order, the steps that were applied and whose
+	// readiness gate passed on this run. Callers use this to report
+	// per-resource status even when the pipeline stops partway through.
+	Completed []string
+	// Requeue is true when a step's readiness gate has not yet passed.
+	Requeue bool
+	// Step is the name of the step that caused the pipeline to stop, either
+	// because it isn't ready yet or because applying/waiting for it errored.
+	// Empty when every step completed.
+	Step string
+	// Reason is the human-readable readiness reason for Step, when Requeue
+	// is true.
+	Reason string
+}
+
+// Run applies every Step in order. It returns as soon as a Step's readiness
+// gate fails to pass, leaving every later Step untouched until that happens.
+// Result.Step and the returned error are both populated when a step errors,
+// so callers can attribute the failure to the right resource.
+func (p *Pipeline) Run(ctx context.Context) (Result, error) {
+	result := Result{}
+
+	for _, step := range p.Steps {
+		if err := p.Client.Apply(ctx, step.Object); err != nil {
+			result.Step = step.Name
+			return result, fmt.Errorf("applying %s: %w", step.Name, err)
+		}
+
+		if step.Ready == nil {
+			result.Completed = append(result.Completed, step.Name)
+			continue
+		}
+
+		key := types.NamespacedName{Name: step.Object.GetName(), Namespace: step.Object.GetNamespace()}
+		ready, reason, err := p.Client.WaitFor(ctx, key, newEmpty(step.Object), step.Ready)
+		if err != nil {
+			result.Step = step.Name
+			return result, fmt.Errorf("waiting for %s: %w", step.Name, err)
+		}
+		if !ready {
+			result.Requeue = true
+			result.Step = step.Name
+			result.Reason = reason
+			return result, nil
+		}
+
+		if step.AfterReady != nil {
+			if err := step.AfterReady(ctx); err != nil {
+				result.Step = step.Name
+				return result, fmt.Errorf("after %s ready: %w", step.Name, err)
+			}
+		}
+
+		result.Completed = append(result.Completed, step.Name)
+	}
+
+	return result, nil
+}
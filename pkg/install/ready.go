@@ -0,0 +1,80 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PVCBound is a ReadyFunc that waits for a PersistentVolumeClaim to reach
+// the Bound phase.
+func PVCBound(obj client.Object) (bool, string) {
+	pvc, ok := obj.(*corev1.PersistentVolumeClaim)
+	if !ok {
+		return false, "unexpected object type"
+	}
+	return pvc.Status.Phase == corev1.ClaimBound, fmt.Sprintf("phase=%s", pvc.Status.Phase)
+}
+
+// UnstructuredCompleted is a ReadyFunc that waits for status.completed to be
+// true on an unstructured resource, the convention used by the MariaDB
+// schema CR created via glance.SchemaObject.
+func UnstructuredCompleted(obj client.Object) (bool, string) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return false, "unexpected object type"
+	}
+	completed, _, _ := unstructured.NestedBool(u.UnstructuredContent(), "status", "completed")
+	if completed {
+		return true, "completed"
+	}
+	return false, "not completed"
+}
+
+// JobSucceeded is a ReadyFunc that waits for a Job to report at least one
+// successful Pod completion.
+func JobSucceeded(obj client.Object) (bool, string) {
+	job, ok := obj.(*batchv1.Job)
+	if !ok {
+		return false, "unexpected object type"
+	}
+	if job.Status.Succeeded > 0 {
+		return true, "succeeded"
+	}
+	return false, fmt.Sprintf("succeeded=%d", job.Status.Succeeded)
+}
+
+// DeploymentReady returns a ReadyFunc that waits for a Deployment's
+// ReadyReplicas to reach replicas.
+func DeploymentReady(replicas int32) ReadyFunc {
+	return func(obj client.Object) (bool, string) {
+		deployment, ok := obj.(*appsv1.Deployment)
+		if !ok {
+			return false, "unexpected object type"
+		}
+		if deployment.Status.ReadyReplicas == replicas {
+			return true, fmt.Sprintf("replicas=%d", replicas)
+		}
+		return false, fmt.Sprintf("readyReplicas=%d/%d", deployment.Status.ReadyReplicas, replicas)
+	}
+}
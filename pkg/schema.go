@@ -0,0 +1,42 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package glance
+
+import (
+	glancev1beta1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// SchemaObject returns the unstructured MariaDBDatabase CR used to request
+// the Glance schema from mariadb-operator. It is built unstructured so this
+// operator does not need to vendor the mariadb-operator API types.
+func SchemaObject(instance *glancev1beta1.GlanceAPI) (unstructured.Unstructured, error) {
+	schema := unstructured.Unstructured{}
+	schema.SetGroupVersionKind(MariaDBDatabaseGVK())
+	schema.SetName(instance.Name)
+	schema.SetNamespace(instance.Namespace)
+	schema.SetLabels(instance.GetPodLabels())
+
+	if err := unstructured.SetNestedField(schema.Object, instance.Spec.DatabaseInstance, "spec", "databaseInstance"); err != nil {
+		return schema, err
+	}
+	if err := unstructured.SetNestedField(schema.Object, "glance", "spec", "name"); err != nil {
+		return schema, err
+	}
+
+	return schema, nil
+}
@@ -0,0 +1,51 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package glance
+
+import (
+	glancev1beta1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Service returns the ClusterIP Service exposing the Glance API pods
+func Service(instance *glancev1beta1.GlanceAPI, scheme *runtime.Scheme) *corev1.Service {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      instance.Name,
+			Namespace: instance.Namespace,
+			Labels:    instance.GetPodLabels(),
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: instance.GetPodLabels(),
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "glance",
+					Port:       9292,
+					TargetPort: intstr.FromInt(9292),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+		},
+	}
+
+	ctrl.SetControllerReference(instance, service, scheme)
+	return service
+}